@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type shortenRequest struct {
+	URL string `json:"url"`
+}
+
+// shortenResponse is returned by both POST /api/v1/shorten and the JSON
+// form of /add.
+type shortenResponse struct {
+	Key   string `json:"key"`
+	Short string `json:"short"`
+}
+
+// resolveResponse is returned by GET /api/v1/resolve/{key}.
+type resolveResponse struct {
+	Key string `json:"key"`
+	URL string `json:"url"`
+}
+
+// ShortenAPI handles POST /api/v1/shorten: body is {"url":"..."}, the
+// response is {"key":"...","short":"..."}.
+func ShortenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req shortenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateURL(req.URL); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var key string
+	if err := store.Put(&req.URL, &key); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, shortenResponse{
+		Key:   key,
+		Short: fmt.Sprintf("http://%s/%s", *hostname, key),
+	})
+}
+
+// ResolveAPI handles GET /api/v1/resolve/{key}: it returns the stored URL
+// as JSON, or 404 if the key is unknown.
+func ResolveAPI(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/api/v1/resolve/")
+	var url string
+	if err := store.Get(&key, &url); err != nil {
+		http.Error(w, "key not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, resolveResponse{Key: key, URL: url})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// wantsJSON reports whether r prefers a JSON response over HTML, based on
+// its Accept header.
+func wantsJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
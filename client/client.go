@@ -0,0 +1,74 @@
+// Package client is a small Go client for the goto URL shortener's JSON
+// API, so other programs can shorten and resolve URLs without
+// screen-scraping the HTML form.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Client talks to a goto server's /api/v1 endpoints.
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// New returns a Client that talks to the goto server at baseURL, e.g.
+// "http://localhost:3000".
+func New(baseURL string) *Client {
+	return &Client{BaseURL: baseURL, HTTP: http.DefaultClient}
+}
+
+// ShortenResponse is the JSON shape returned by Shorten.
+type ShortenResponse struct {
+	Key   string `json:"key"`
+	Short string `json:"short"`
+}
+
+// Shorten asks the server to shorten url and returns its key and the
+// full shortened URL.
+func (c *Client) Shorten(url string) (*ShortenResponse, error) {
+	body, err := json.Marshal(map[string]string{"url": url})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.HTTP.Post(c.BaseURL+"/api/v1/shorten", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("client: shorten: unexpected status %s", resp.Status)
+	}
+	var out ShortenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ResolveResponse is the JSON shape returned by Resolve.
+type ResolveResponse struct {
+	Key string `json:"key"`
+	URL string `json:"url"`
+}
+
+// Resolve looks up the URL stored under key.
+func (c *Client) Resolve(key string) (*ResolveResponse, error) {
+	resp, err := c.HTTP.Get(c.BaseURL + "/api/v1/resolve/" + key)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("client: resolve: unexpected status %s", resp.Status)
+	}
+	var out ResolveResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"hash/fnv"
+	"log"
+	"strconv"
+	"sync/atomic"
+)
+
+// KeyGenerator produces a candidate short key for url. URLStore.Put calls
+// GenKey in a loop, incrementing attempt each time the previous candidate
+// collided with an existing key.
+type KeyGenerator interface {
+	GenKey(url string, attempt int) string
+}
+
+// base62Digits are the characters used to encode a monotonic counter into
+// a short key.
+const base62Digits = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// CounterKeyGen generates keys by base62-encoding a monotonically
+// increasing counter. Keys are short but reveal insertion order and
+// count, which makes this the simplest but least private strategy.
+type CounterKeyGen struct {
+	n uint64 // accessed atomically
+}
+
+func (g *CounterKeyGen) GenKey(url string, attempt int) string {
+	n := atomic.AddUint64(&g.n, 1) - 1
+	return toBase62(n)
+}
+
+func toBase62(n uint64) string {
+	if n == 0 {
+		return string(base62Digits[0])
+	}
+	var buf []byte
+	for n > 0 {
+		buf = append([]byte{base62Digits[n%62]}, buf...)
+		n /= 62
+	}
+	return string(buf)
+}
+
+// HashKeyGen generates keys by truncating an FNV-1a hash of the URL to
+// Len characters. On collision, Put retries with an incremented attempt,
+// which is mixed into the hash as a linear probe.
+type HashKeyGen struct {
+	Len int
+}
+
+func (g *HashKeyGen) GenKey(url string, attempt int) string {
+	h := fnv.New64a()
+	h.Write([]byte(url))
+	if attempt > 0 {
+		h.Write([]byte{byte(attempt)})
+	}
+	key := strconv.FormatUint(h.Sum64(), 36)
+	if len(key) > g.Len {
+		key = key[len(key)-g.Len:]
+	}
+	return key
+}
+
+// RandomKeyGen generates keys by base64-encoding Len cryptographically
+// random bytes, so keys carry no information about the URL or insertion
+// order.
+type RandomKeyGen struct {
+	Len int
+	n   uint64 // fallback counter, used only if crypto/rand fails
+}
+
+func (g *RandomKeyGen) GenKey(url string, attempt int) string {
+	b := make([]byte, g.Len)
+	if _, err := rand.Read(b); err != nil {
+		log.Println("RandomKeyGen:", err)
+		return g.fallbackKey(attempt)
+	}
+	key := base64.RawURLEncoding.EncodeToString(b)
+	if len(key) > g.Len {
+		key = key[:g.Len]
+	}
+	return key
+}
+
+// fallbackKey is used when crypto/rand fails. It still varies with
+// attempt and a monotonic counter, so URLStore.Put's retry loop can make
+// progress instead of busy-spinning on the same key forever.
+func (g *RandomKeyGen) fallbackKey(attempt int) string {
+	n := atomic.AddUint64(&g.n, 1) - 1
+	return toBase62(n) + strconv.Itoa(attempt)
+}
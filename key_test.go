@@ -0,0 +1,65 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// collidingKeyGen returns a fixed key for the first collide attempts,
+// then defers to delegate. It lets tests force URLStore.Put's retry loop
+// deterministically, without depending on a real hash collision.
+type collidingKeyGen struct {
+	collide  int
+	delegate KeyGenerator
+}
+
+func (g *collidingKeyGen) GenKey(url string, attempt int) string {
+	if attempt < g.collide {
+		return "dup"
+	}
+	return g.delegate.GenKey(url, attempt)
+}
+
+func TestPutRetriesOnCollision(t *testing.T) {
+	s := NewURLStore(filepath.Join(t.TempDir(), "store.gob"), &collidingKeyGen{collide: 2, delegate: &CounterKeyGen{}})
+	defer s.Close()
+
+	first := "http://a.example"
+	var key1 string
+	if err := s.Put(&first, &key1); err != nil {
+		t.Fatal(err)
+	}
+	if key1 != "dup" {
+		t.Fatalf("first Put: want the colliding key to still be free, got %q", key1)
+	}
+
+	second := "http://b.example"
+	var key2 string
+	if err := s.Put(&second, &key2); err != nil {
+		t.Fatal(err)
+	}
+	if key2 == "dup" {
+		t.Fatalf("second Put: want it to retry past the taken key, got %q again", key2)
+	}
+}
+
+func TestHashKeyGenProbesOnCollision(t *testing.T) {
+	g := &HashKeyGen{Len: 8}
+	k0 := g.GenKey("http://example.com", 0)
+	k1 := g.GenKey("http://example.com", 1)
+	if k0 == k1 {
+		t.Fatalf("GenKey must vary with attempt so Put can probe past a collision, got %q for both attempt 0 and 1", k0)
+	}
+}
+
+func TestCounterKeyGenIsMonotonic(t *testing.T) {
+	g := &CounterKeyGen{}
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		k := g.GenKey("http://example.com", 0)
+		if seen[k] {
+			t.Fatalf("CounterKeyGen produced duplicate key %q", k)
+		}
+		seen[k] = true
+	}
+}
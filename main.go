@@ -1,54 +1,150 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"log"
 	"net/http"
+	"net/rpc"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
-var store *URLStore
+// shutdownTimeout bounds how long awaitShutdown waits for in-flight
+// requests to finish before giving up on a graceful stop.
+const shutdownTimeout = 5 * time.Second
+
+// Store is the surface the HTTP handlers need. URLStore satisfies it
+// directly on the master; ProxyStore satisfies it on a slave by proxying
+// to the master over RPC.
+type Store interface {
+	Get(key, url *string) error
+	Put(url, key *string) error
+}
+
+var store Store
 
 var (
 	listenAddr = flag.String("http", ":3000", "http listen address")
 	dataFile   = flag.String("file", "store.gob", "data store file name")
 	hostname   = flag.String("host", "localhost:3000", "host name and port")
+	masterAddr = flag.String("master", "", "RPC master address (empty means run as master)")
+	keygenFlag = flag.String("keygen", "counter", "key generation strategy: counter, hash, random")
 )
 
+// newKeyGenerator builds the KeyGenerator named by -keygen.
+func newKeyGenerator(name string) KeyGenerator {
+	switch name {
+	case "hash":
+		return &HashKeyGen{Len: 8}
+	case "random":
+		return &RandomKeyGen{Len: 8}
+	case "counter":
+		return &CounterKeyGen{}
+	default:
+		log.Fatalf("unknown -keygen %q (want counter, hash, or random)", name)
+		return nil
+	}
+}
+
 func main() {
 	flag.Parse()
 	fmt.Println("Server listening at", *hostname)
-	store = NewURLStore(*dataFile)
+
+	srv := &http.Server{Addr: *listenAddr}
+
+	if *masterAddr != "" {
+		store = NewProxyStore(*masterAddr)
+	} else {
+		urlStore := NewURLStore(*dataFile, newKeyGenerator(*keygenFlag))
+		if err := rpc.RegisterName("Store", urlStore); err != nil {
+			log.Fatal("rpc.RegisterName:", err)
+		}
+		rpc.HandleHTTP()
+		go awaitShutdown(srv, urlStore)
+		store = urlStore
+	}
+
 	http.HandleFunc("/", Redirect)
 	http.HandleFunc("/add", Add)
-	http.ListenAndServe(*listenAddr, nil)
+	http.HandleFunc("/api/v1/shorten", ShortenAPI)
+	http.HandleFunc("/api/v1/resolve/", ResolveAPI)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
+
+// awaitShutdown blocks until the process receives SIGINT or SIGTERM, then
+// stops srv from accepting new requests and waits for in-flight ones to
+// finish before flushing the store's pending writes to disk. Shutting
+// the HTTP server down first guarantees no Put is still running when the
+// store's save channel is closed.
+func awaitShutdown(srv *http.Server, s *URLStore) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	<-sig
+	fmt.Println("shutting down, flushing store...")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		fmt.Println("http.Server.Shutdown:", err)
+	}
+	if err := s.Close(); err != nil {
+		fmt.Println("URLStore:", err)
+	}
+	os.Exit(0)
 }
 
 func Redirect(w http.ResponseWriter, r *http.Request) {
 	key := r.URL.Path[1:]
-	url := store.Get(key)
-	if url == "" {
-		http.Redirect(w, r, "/add", http.StatusFound)
+	var url string
+	if err := store.Get(&key, &url); err != nil {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusNotFound)
+		notFoundTmpl.Execute(w, nil)
 		return
 	}
 	http.Redirect(w, r, url, http.StatusFound)
 }
 
+// Add serves the add-URL form and handles its submission. It renders
+// HTML by default, but returns JSON instead when the request prefers
+// application/json, so the same endpoint backs both the browser form and
+// programmatic callers.
 func Add(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/html")
-	url := r.FormValue("url")
-	if url == "" {
-		fmt.Fprint(w, addForm)
+	raw := r.FormValue("url")
+	if raw == "" {
+		if wantsJSON(r) {
+			http.Error(w, "missing url", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		addTmpl.Execute(w, addPage{})
+		return
+	}
+	if err := validateURL(raw); err != nil {
+		if wantsJSON(r) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadRequest)
+		addTmpl.Execute(w, addPage{Error: err.Error(), URL: raw})
 		return
 	}
-	key := store.Put(url)
-	fmt.Fprintf(w, "%s", key)
+	var key string
+	if err := store.Put(&raw, &key); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	short := fmt.Sprintf("http://%s/%s", *hostname, key)
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, shortenResponse{Key: key, Short: short})
+		return
+	}
+	w.Header().Set("Content-Type", "text/html")
+	addedTmpl.Execute(w, addedPage{Short: short})
 }
-
-const addForm = `
-<html><body>
-<form method="POST" action="/add">
-URL: <input type="text" name="url">
-<input type="submit" value="Add">
-</form>
-</html></body>
-`
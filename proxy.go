@@ -0,0 +1,54 @@
+package main
+
+import (
+	"log"
+	"net/rpc"
+	"sync"
+)
+
+// ProxyStore is the slave-side counterpart to URLStore: it forwards Get
+// and Put to a master URLStore over RPC, keeping a small local cache so
+// repeated Gets for the same key don't round-trip. It implements the
+// Store interface so the HTTP handlers don't need to know which mode the
+// process is running in. It deliberately has no Count: the slave's cache
+// size isn't the master's URL count, and nothing needs that total today.
+type ProxyStore struct {
+	mu     sync.RWMutex
+	cache  map[string]string
+	client *rpc.Client
+}
+
+func NewProxyStore(addr string) *ProxyStore {
+	client, err := rpc.DialHTTP("tcp", addr)
+	if err != nil {
+		log.Fatal("ProxyStore: dialing master: ", err)
+	}
+	return &ProxyStore{cache: make(map[string]string), client: client}
+}
+
+func (s *ProxyStore) Get(key, url *string) error {
+	s.mu.RLock()
+	if u, ok := s.cache[*key]; ok {
+		*url = u
+		s.mu.RUnlock()
+		return nil
+	}
+	s.mu.RUnlock()
+	if err := s.client.Call("Store.Get", key, url); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.cache[*key] = *url
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *ProxyStore) Put(url, key *string) error {
+	if err := s.client.Call("Store.Put", url, key); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.cache[*key] = *url
+	s.mu.Unlock()
+	return nil
+}
@@ -1,30 +1,132 @@
 package main
 
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"sync"
+)
+
+// saveQueueLength bounds how many unsaved records Put can buffer before it
+// starts blocking on the writer goroutine.
+const saveQueueLength = 1000
+
 type URLStore struct {
+	mu   sync.RWMutex
 	urls map[string]string
+
+	keyGen KeyGenerator
+
+	file *os.File
+	save chan record
+	done chan struct{}
+}
+
+type record struct {
+	Key, URL string
 }
 
-func (s *URLStore) Get(key string) string {
-	return s.urls[key]
+func NewURLStore(filename string, keyGen KeyGenerator) *URLStore {
+	s := &URLStore{
+		urls:   make(map[string]string),
+		keyGen: keyGen,
+		save:   make(chan record, saveQueueLength),
+		done:   make(chan struct{}),
+	}
+	f, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		log.Fatal("URLStore:", err)
+	}
+	s.file = f
+	if err := s.load(); err != nil {
+		log.Println("URLStore:", err)
+	}
+	go s.saveLoop()
+	return s
+}
+
+// Get and Set use the (argType, replyType *T) error signature required by
+// net/rpc so URLStore can be registered directly as an RPC service on the
+// master; see ProxyStore for the slave side of that split.
+
+func (s *URLStore) Get(key, url *string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if u, ok := s.urls[*key]; ok {
+		*url = u
+		return nil
+	}
+	return errors.New("key not found")
 }
 
-func (s *URLStore) Set(key, url string) bool {
-	if _, present := s.urls[key]; present {
-		return false
+func (s *URLStore) Set(key, url *string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, present := s.urls[*key]; present {
+		return errors.New("key already exists")
 	}
-	s.urls[key] = url
-	return true
+	s.urls[*key] = *url
+	return nil
 }
 
 func (s *URLStore) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return len(s.urls)
 }
 
-func (s *URLStore) Put(url string) string {
+func (s *URLStore) Put(url, key *string) error {
+	for attempt := 0; ; attempt++ {
+		k := s.keyGen.GenKey(*url, attempt)
+		if err := s.Set(&k, url); err == nil {
+			*key = k
+			break
+		}
+	}
+	s.save <- record{*key, *url}
+	return nil
+}
+
+// saveLoop drains records pushed onto s.save and appends each one to the
+// log file, keeping disk I/O off the Put/Set hot path. It runs for the
+// lifetime of the store and exits once s.save is closed by Close.
+func (s *URLStore) saveLoop() {
+	defer close(s.done)
+	e := gob.NewEncoder(s.file)
+	for r := range s.save {
+		if err := e.Encode(r); err != nil {
+			log.Println("URLStore:", err)
+		}
+	}
+}
+
+// Close stops accepting further saves, blocks until the writer goroutine
+// has flushed everything already queued, and closes the log file. It
+// should be called once, during shutdown.
+func (s *URLStore) Close() error {
+	close(s.save)
+	<-s.done
+	return s.file.Close()
+}
+
+// load replays the log file into the in-memory map. It is called once,
+// from NewURLStore, before the store is handed out to callers.
+func (s *URLStore) load() error {
+	if _, err := s.file.Seek(0, 0); err != nil {
+		return err
+	}
+	d := gob.NewDecoder(s.file)
 	for {
-		key := genKey(s.Count()) // generate the short URL
-		if ok := s.Set(key, url); ok {
-			return key
+		var r record
+		if err := d.Decode(&r); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
 		}
+		s.urls[r.Key] = r.URL
 	}
+	return nil
 }
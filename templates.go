@@ -0,0 +1,44 @@
+package main
+
+import "html/template"
+
+var (
+	addTmpl      = template.Must(template.New("add").Parse(addHTML))
+	addedTmpl    = template.Must(template.New("added").Parse(addedHTML))
+	notFoundTmpl = template.Must(template.New("notfound").Parse(notFoundHTML))
+)
+
+// addPage is the data for addHTML: the form, optionally re-populated with
+// the URL that failed validation and the reason it failed.
+type addPage struct {
+	Error string
+	URL   string
+}
+
+// addedPage is the data for addedHTML: the full shortened URL.
+type addedPage struct {
+	Short string
+}
+
+const addHTML = `
+<html><body>
+{{if .Error}}<p style="color:red">{{.Error}}</p>{{end}}
+<form method="POST" action="/add">
+URL: <input type="text" name="url" value="{{.URL}}">
+<input type="submit" value="Add">
+</form>
+</body></html>
+`
+
+const addedHTML = `
+<html><body>
+<p>Added: <a href="{{.Short}}">{{.Short}}</a></p>
+</body></html>
+`
+
+const notFoundHTML = `
+<html><body>
+<h1>404 Not Found</h1>
+<p>No URL found for that key. <a href="/add">Add one?</a></p>
+</body></html>
+`
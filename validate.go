@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"net/url"
+)
+
+// validateURL checks that raw is an absolute http(s) URL suitable for
+// shortening: it must have an http or https scheme, a non-empty host, and
+// no embedded credentials or fragment.
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("URL must start with http:// or https://")
+	}
+	if u.Host == "" {
+		return errors.New("URL must include a host")
+	}
+	if u.User != nil {
+		return errors.New("URL must not contain credentials")
+	}
+	if u.Fragment != "" {
+		return errors.New("URL must not contain a fragment")
+	}
+	return nil
+}